@@ -0,0 +1,50 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha5
+
+import v1 "k8s.io/api/core/v1"
+
+// Constraints are the cloud-provider-agnostic fields of a Provisioner's spec that narrow which Nodes may be
+// launched for a given set of pods. Cloud providers embed this and add their own provider-specific fields, e.g.
+// v1alpha1.Constraints embeds this and adds AWS.
+type Constraints struct {
+	// Requirements narrows the set of zones, capacity types, and instance types a Node may use. It accumulates
+	// pod-level requirements as pods are added to a scheduling.Node.
+	Requirements Requirements `json:"requirements,omitempty"`
+	// Tags are applied to launched Nodes (and their volumes) in addition to the cluster's default tags.
+	// +optional
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// DeepCopy returns a copy of Constraints that shares no state with the receiver's mutable fields.
+func (c *Constraints) DeepCopy() *Constraints {
+	if c == nil {
+		return nil
+	}
+	requirements := make([]v1.NodeSelectorRequirement, 0, len(c.Requirements.Requirements))
+	for _, requirement := range c.Requirements.Requirements {
+		requirements = append(requirements, *requirement.DeepCopy())
+	}
+	out := &Constraints{
+		Requirements: NewRequirements(requirements...),
+	}
+	if c.Tags != nil {
+		out.Tags = make(map[string]string, len(c.Tags))
+		for k, v := range c.Tags {
+			out.Tags[k] = v
+		}
+	}
+	return out
+}
@@ -0,0 +1,106 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha5
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// labelZoneType mirrors v1alpha1.LabelZoneType. It's duplicated here rather than imported to avoid this package
+// importing the AWS cloud provider package it's meant to be cloud-provider-agnostic from.
+const labelZoneType = "karpenter.k8s.aws/zone-type"
+
+// Requirements is a decomposed set of node selector requirements, keyed by label, that a candidate Node must satisfy.
+// Pods accumulate onto a scheduling.Node's Requirements as they're added, narrowing the set of instance types and
+// zones/capacity-types that remain eligible.
+type Requirements struct {
+	Requirements []v1.NodeSelectorRequirement
+}
+
+// NewRequirements constructs a Requirements from a set of node selector requirements.
+func NewRequirements(requirements ...v1.NodeSelectorRequirement) Requirements {
+	return Requirements{Requirements: requirements}
+}
+
+// NewPodRequirements translates a pod's node selector and node affinity terms into a Requirements.
+func NewPodRequirements(pod *v1.Pod) Requirements {
+	var requirements []v1.NodeSelectorRequirement
+	for key, value := range pod.Spec.NodeSelector {
+		requirements = append(requirements, v1.NodeSelectorRequirement{Key: key, Operator: v1.NodeSelectorOpIn, Values: []string{value}})
+	}
+	if pod.Spec.Affinity != nil && pod.Spec.Affinity.NodeAffinity != nil && pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		for _, term := range pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+			requirements = append(requirements, term.MatchExpressions...)
+		}
+	}
+	return NewRequirements(requirements...)
+}
+
+// values returns the allowed values for key across all "In" requirements, unioned together.
+func (r Requirements) values(key string) sets.String {
+	values := sets.NewString()
+	for _, requirement := range r.Requirements {
+		if requirement.Key == key && requirement.Operator == v1.NodeSelectorOpIn {
+			values.Insert(requirement.Values...)
+		}
+	}
+	return values
+}
+
+// Zones returns the set of topology zones this Requirements allows, or an empty set if unconstrained.
+func (r Requirements) Zones() sets.String {
+	return r.values(v1.LabelTopologyZone)
+}
+
+// CapacityTypes returns the set of capacity types (e.g. spot, on-demand) this Requirements allows, or an empty set
+// if unconstrained.
+func (r Requirements) CapacityTypes() sets.String {
+	return r.values(LabelCapacityType)
+}
+
+// ZoneTypes returns the set of zone-types (availability-zone, local-zone, wavelength-zone) this Requirements allows.
+// An empty set means the pod hasn't opted into anything and callers should fall back to their own default.
+func (r Requirements) ZoneTypes() sets.String {
+	return r.values(labelZoneType)
+}
+
+// Add returns a new Requirements with additional requirements appended.
+func (r Requirements) Add(requirements ...v1.NodeSelectorRequirement) Requirements {
+	return NewRequirements(append(append([]v1.NodeSelectorRequirement{}, r.Requirements...), requirements...)...)
+}
+
+// Compatible returns an error if other conflicts with r on any key where both specify disjoint "In" values.
+func (r Requirements) Compatible(other Requirements) error {
+	for _, requirement := range other.Requirements {
+		if requirement.Operator != v1.NodeSelectorOpIn {
+			continue
+		}
+		existing := r.values(requirement.Key)
+		if existing.Len() == 0 {
+			continue
+		}
+		if existing.Intersection(sets.NewString(requirement.Values...)).Len() == 0 {
+			return fmt.Errorf("incompatible requirement %s, have %v, want %v", requirement.Key, existing.List(), requirement.Values)
+		}
+	}
+	return nil
+}
+
+func (r Requirements) String() string {
+	return fmt.Sprintf("%v", r.Requirements)
+}
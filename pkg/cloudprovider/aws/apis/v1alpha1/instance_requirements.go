@@ -0,0 +1,66 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// InstanceRequirements mirrors ec2.InstanceRequirementsRequest and lets a
+// Provisioner describe the envelope of acceptable instance types (attribute
+// based instance selection) instead of enumerating them. When set, it is
+// passed straight through to CreateFleet as InstanceRequirements on the
+// override and takes precedence over an explicit InstanceType matrix.
+type InstanceRequirements struct {
+	// VCpuCount bounds the number of vCPUs, inclusive. A nil Max means unbounded.
+	// +optional
+	VCpuCount *CountRange `json:"vCpuCount,omitempty"`
+	// MemoryMiB bounds instance memory in MiB, inclusive. A nil Max means unbounded.
+	// +optional
+	MemoryMiB *CountRange `json:"memoryMiB,omitempty"`
+	// AcceleratorCount bounds the number of GPUs/accelerators, inclusive.
+	// +optional
+	AcceleratorCount *CountRange `json:"acceleratorCount,omitempty"`
+	// AllowedArchitectures lists CPU architectures, e.g. "x86_64", "arm64". It is accepted but not passed to
+	// CreateFleet, so it does not restrict the architecture of the instance types EC2 selects.
+	// +optional
+	AllowedArchitectures []string `json:"allowedArchitectures,omitempty"`
+	// BareMetal controls whether bare metal instance types may be selected.
+	// +optional
+	BareMetal *string `json:"bareMetal,omitempty"`
+	// BurstablePerformance controls whether burstable (T-family) instance types may be selected.
+	// +optional
+	BurstablePerformance *string `json:"burstablePerformance,omitempty"`
+	// NetworkBandwidthGbps bounds the baseline network bandwidth in Gbps, inclusive.
+	// +optional
+	NetworkBandwidthGbps *Float64Range `json:"networkBandwidthGbps,omitempty"`
+	// AllowedInstanceFamilies restricts selection to these instance families, e.g. "m5.*", "c6g.*".
+	// +optional
+	AllowedInstanceFamilies []string `json:"allowedInstanceFamilies,omitempty"`
+	// ExcludedInstanceFamilies removes these instance families from consideration, e.g. "t2.*".
+	// +optional
+	ExcludedInstanceFamilies []string `json:"excludedInstanceFamilies,omitempty"`
+	// SpotMaxPricePercentageOverLowestPrice bounds spot bids as a percentage over the cheapest On-Demand price.
+	// +optional
+	SpotMaxPricePercentageOverLowestPrice *int64 `json:"spotMaxPricePercentageOverLowestPrice,omitempty"`
+}
+
+// CountRange bounds an integer quantity. A nil Max means unbounded.
+type CountRange struct {
+	Min int64  `json:"min"`
+	Max *int64 `json:"max,omitempty"`
+}
+
+// Float64Range bounds a floating point quantity. A nil Max means unbounded.
+type Float64Range struct {
+	Min float64  `json:"min"`
+	Max *float64 `json:"max,omitempty"`
+}
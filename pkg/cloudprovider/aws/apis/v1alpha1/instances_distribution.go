@@ -0,0 +1,39 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// InstancesDistribution mirrors an ASG mixed-instances policy, letting a Provisioner require a baseline of
+// on-demand capacity and split the remainder between on-demand and spot according to a fixed ratio, rather than
+// Karpenter choosing spot-or-on-demand per CreateFleet call.
+type InstancesDistribution struct {
+	// OnDemandBaseCapacity is the minimum amount of target capacity that must be filled with on-demand instances.
+	// +optional
+	OnDemandBaseCapacity *int64 `json:"onDemandBaseCapacity,omitempty"`
+	// OnDemandPercentageAboveBaseCapacity is the percentage of target capacity above OnDemandBaseCapacity that
+	// should be on-demand, with the remainder filled by spot.
+	// +optional
+	OnDemandPercentageAboveBaseCapacity *int64 `json:"onDemandPercentageAboveBaseCapacity,omitempty"`
+	// SpotAllocationStrategy is the strategy CreateFleet uses to allocate spot capacity, e.g.
+	// "lowest-price", "capacity-optimized", "capacity-optimized-prioritized", "price-capacity-optimized".
+	// +optional
+	SpotAllocationStrategy *string `json:"spotAllocationStrategy,omitempty"`
+	// OnDemandAllocationStrategy is the strategy CreateFleet uses to allocate on-demand capacity, e.g.
+	// "lowest-price", "prioritized".
+	// +optional
+	OnDemandAllocationStrategy *string `json:"onDemandAllocationStrategy,omitempty"`
+	// SpotMaxPrice is the maximum price per hour Karpenter will pay for a spot instance.
+	// +optional
+	SpotMaxPrice *string `json:"spotMaxPrice,omitempty"`
+}
@@ -0,0 +1,44 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import "github.com/aws/karpenter/pkg/apis/provisioning/v1alpha5"
+
+// AWS contains the provider-specific constraints for a Provisioner running on the AWS cloud provider.
+type AWS struct {
+	// InstanceRequirements, if set, has Karpenter hand EC2 an attribute envelope (vCPU/memory ranges, architectures,
+	// etc.) instead of an enumerated matrix of instance types, letting Fleet pick the best fit itself.
+	// +optional
+	InstanceRequirements *InstanceRequirements `json:"instanceRequirements,omitempty"`
+	// InstancesDistribution mirrors an ASG mixed-instances policy, splitting target capacity between on-demand and
+	// spot instead of Karpenter choosing a single capacityType for the whole CreateFleet call.
+	// +optional
+	InstancesDistribution *InstancesDistribution `json:"instancesDistribution,omitempty"`
+	// SubnetSelectionStrategy picks which subnet wins within an AZ when more than one is eligible, e.g.
+	// SubnetSelectionMostAvailableIPs (the default), SubnetSelectionRoundRobin. Empty selects the default.
+	// +optional
+	SubnetSelectionStrategy string `json:"subnetSelectionStrategy,omitempty"`
+	// SubnetSelector discovers subnets by tag. Each entry is ANDed together; a value of "*" matches any value for
+	// that tag key, otherwise the subnet's tag value must equal it exactly. Required: a Provisioner with no
+	// SubnetSelector entries is rejected rather than allowed to fall back to every subnet in the account.
+	// +optional
+	SubnetSelector map[string]string `json:"subnetSelector,omitempty"`
+}
+
+// Constraints adds AWS-specific fields to the cloud-provider-agnostic v1alpha5.Constraints.
+type Constraints struct {
+	*v1alpha5.Constraints
+	AWS AWS `json:"aws,omitempty"`
+}
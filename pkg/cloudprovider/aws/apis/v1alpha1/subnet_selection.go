@@ -0,0 +1,32 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+const (
+	// SubnetSelectionMostAvailableIPs picks, per AZ, the subnet with the most available IP addresses. This is the
+	// default strategy.
+	SubnetSelectionMostAvailableIPs = "most-available-ips"
+	// SubnetSelectionLeastAllocatedNodes reads karpenter-managed node counts per zone and picks one subnet per AZ.
+	// Nodes are not counted per subnet, so within an AZ the first eligible subnet is chosen.
+	SubnetSelectionLeastAllocatedNodes = "least-allocated-nodes"
+	// SubnetSelectionRoundRobin cycles through the eligible subnets within an AZ on successive subnet selections (one
+	// per launch template and capacity pool of each launch).
+	SubnetSelectionRoundRobin = "round-robin"
+)
+
+// AZBalanced (spreading new nodes across AZs to match a target ratio, for stateful workloads and for matching the
+// AZ-per-worker-list pattern some cluster installers use) is intentionally not offered as a selectable strategy:
+// doing it correctly means coordinating target ratios across concurrent CreateFleet calls, which a per-AZ subnet
+// tiebreaker can't do on its own. Add it back once that coordination exists.
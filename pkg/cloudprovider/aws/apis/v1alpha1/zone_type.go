@@ -0,0 +1,37 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+const (
+	// LabelZoneType is applied to launched Nodes with the zone-type of the AZ they're scheduled into, as reported by
+	// ec2:DescribeAvailabilityZones. Candidate scheduling Nodes are not labeled; they carry it only as a requirement
+	// from pods that select it. Pods must explicitly select it via a node selector/requirement
+	// to land on anything other than a standard availability-zone.
+	LabelZoneType = "karpenter.k8s.aws/zone-type"
+
+	// ZoneTypeAvailabilityZone is a standard, region-resident availability zone. This is the default Karpenter will
+	// schedule into when a pod does not request a specific zone-type.
+	ZoneTypeAvailabilityZone = "availability-zone"
+	// ZoneTypeLocalZone is an AWS Local Zone: a compute/storage extension of a region placed closer to population
+	// centers, with a distinct subset of instance type and pricing offerings.
+	ZoneTypeLocalZone = "local-zone"
+	// ZoneTypeWavelengthZone is an AWS Wavelength Zone embedded within a telecom provider's network.
+	ZoneTypeWavelengthZone = "wavelength-zone"
+)
+
+// DefaultZoneTypes is the set of zone-types Karpenter schedules a pod into when it has not opted into anything via
+// node selectors/requirements. Local Zones and Wavelength Zones have different egress/pricing semantics and must be
+// requested explicitly.
+var DefaultZoneTypes = []string{ZoneTypeAvailabilityZone}
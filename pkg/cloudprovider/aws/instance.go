@@ -16,21 +16,27 @@ package aws
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/avast/retry-go"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	cache "github.com/patrickmn/go-cache"
 	"go.uber.org/multierr"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/pkg/logging"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/aws/karpenter/pkg/apis/provisioning/v1alpha5"
 	"github.com/aws/karpenter/pkg/cloudprovider"
@@ -47,38 +53,86 @@ const (
 	// CreationBurst limits the additional burst requests.
 	// https://docs.aws.amazon.com/AWSEC2/latest/APIReference/throttling.html#throttling-limits
 	CreationBurst = 100
+	// clientTokenTTL bounds how long we remember a CreateFleet client token's resulting instance ID. This only needs
+	// to outlive the retry window for a single scheduling round, not the lifetime of the instance.
+	clientTokenTTL = 5 * time.Minute
 )
 
+// errNoCapacityOfferings is returned by getLaunchTemplateConfigs when a capacityType has no eligible
+// zone/instance-type/subnet combination, as distinct from a genuine failure (subnets lookup, zone-types lookup,
+// launch template lookup) reaching EC2. launchInstanceMixed tolerates this per-pool but propagates everything else.
+var errNoCapacityOfferings = errors.New("no capacity offerings are currently available given the constraints")
+
 type InstanceProvider struct {
 	ec2api                 ec2iface.EC2API
+	kubeClient             client.Client
 	instanceTypeProvider   *InstanceTypeProvider
 	subnetProvider         *SubnetProvider
 	launchTemplateProvider *LaunchTemplateProvider
+	// clientTokenCache maps a deterministic CreateFleet client token to the instance ID it produced, so that a retry
+	// of the same scheduling decision after a successful launch reuses the original instance instead of calling
+	// CreateFleet again. It is only filled once launchInstance succeeds; after a failed CreateFleet call (timeout,
+	// throttle, or partial Errors response) nothing is cached and the retry sends the same ClientToken to EC2.
+	clientTokenCache *cache.Cache
+	// subnetSelectors caches one SubnetSelector per strategy so that stateful strategies like RoundRobin hold their
+	// state across scheduling rounds instead of resetting on every CreateFleet call.
+	subnetSelectorsMu sync.Mutex
+	subnetSelectors   map[string]SubnetSelector
 }
 
-func NewInstanceProvider(ec2api ec2iface.EC2API, instanceTypeProvider *InstanceTypeProvider, subnetProvider *SubnetProvider, launchTemplateProvider *LaunchTemplateProvider) *InstanceProvider {
+func NewInstanceProvider(ec2api ec2iface.EC2API, kubeClient client.Client, instanceTypeProvider *InstanceTypeProvider, subnetProvider *SubnetProvider, launchTemplateProvider *LaunchTemplateProvider) *InstanceProvider {
 	return &InstanceProvider{
 		ec2api:                 ec2api,
+		kubeClient:             kubeClient,
 		instanceTypeProvider:   instanceTypeProvider,
 		subnetProvider:         subnetProvider,
 		launchTemplateProvider: launchTemplateProvider,
+		clientTokenCache:       cache.New(clientTokenTTL, 1*time.Minute),
+		subnetSelectors:        map[string]SubnetSelector{},
+	}
+}
+
+// subnetSelectorFor returns the long-lived SubnetSelector for strategy, creating and caching it on first use so
+// that e.g. RoundRobin's call counter persists across scheduling rounds instead of being reconstructed per launch.
+func (p *InstanceProvider) subnetSelectorFor(strategy string) SubnetSelector {
+	p.subnetSelectorsMu.Lock()
+	defer p.subnetSelectorsMu.Unlock()
+	if selector, ok := p.subnetSelectors[strategy]; ok {
+		return selector
 	}
+	selector := NewSubnetSelector(strategy, p.kubeClient)
+	p.subnetSelectors[strategy] = selector
+	return selector
 }
 
 // Create an instance given the constraints.
 // instanceTypes should be sorted by priority for spot capacity type.
 // If spot is not used, the instanceTypes are not required to be sorted
 // because we are using ec2 fleet's lowest-price OD allocation strategy
-func (p *InstanceProvider) Create(ctx context.Context, constraints *v1alpha1.Constraints, instanceTypes []cloudprovider.InstanceType) (*v1.Node, error) {
+func (p *InstanceProvider) Create(ctx context.Context, constraints *v1alpha1.Constraints, instanceTypes []cloudprovider.InstanceType, pods []*v1.Pod, totalTargetCapacity int64) (*v1.Node, error) {
 	instanceTypes = p.filterInstanceTypes(instanceTypes)
 	if len(instanceTypes) > MaxInstanceTypes {
 		instanceTypes = instanceTypes[0:MaxInstanceTypes]
 	}
+	if totalTargetCapacity < 1 {
+		totalTargetCapacity = 1
+	}
 
-	id, err := p.launchInstance(ctx, constraints, instanceTypes)
+	clientToken := clientTokenFor(constraints, pods)
+	if cachedID, ok := p.clientTokenCache.Get(clientToken); ok {
+		logging.FromContext(ctx).Debugf("Reusing instance %s for retried client token", cachedID.(string))
+		instance, err := p.getInstance(ctx, cachedID.(string))
+		if err != nil {
+			return nil, err
+		}
+		return p.instanceToNode(ctx, constraints, instance, instanceTypes), nil
+	}
+
+	id, err := p.launchInstance(ctx, constraints, instanceTypes, clientToken, totalTargetCapacity)
 	if err != nil {
 		return nil, err
 	}
+	p.clientTokenCache.SetDefault(clientToken, aws.StringValue(id))
 	// Get Instance with backoff retry since EC2 is eventually consistent
 	instance := &ec2.Instance{}
 	if err := retry.Do(
@@ -98,7 +152,7 @@ func (p *InstanceProvider) Create(ctx context.Context, constraints *v1alpha1.Con
 		getCapacityType(instance),
 	)
 	// Convert Instance to Node
-	return p.instanceToNode(ctx, instance, instanceTypes), nil
+	return p.instanceToNode(ctx, constraints, instance, instanceTypes), nil
 }
 
 func (p *InstanceProvider) Terminate(ctx context.Context, node *v1.Node) error {
@@ -117,10 +171,13 @@ func (p *InstanceProvider) Terminate(ctx context.Context, node *v1.Node) error {
 	return nil
 }
 
-func (p *InstanceProvider) launchInstance(ctx context.Context, constraints *v1alpha1.Constraints, instanceTypes []cloudprovider.InstanceType) (*string, error) {
+func (p *InstanceProvider) launchInstance(ctx context.Context, constraints *v1alpha1.Constraints, instanceTypes []cloudprovider.InstanceType, clientToken string, totalTargetCapacity int64) (*string, error) {
+	if constraints.AWS.InstancesDistribution != nil {
+		return p.launchInstanceMixed(ctx, constraints, instanceTypes, clientToken, totalTargetCapacity)
+	}
 	capacityType := p.getCapacityType(constraints, instanceTypes)
 	// Get Launch Template Configs, which may differ due to GPU or Architecture requirements
-	launchTemplateConfigs, err := p.getLaunchTemplateConfigs(ctx, constraints, instanceTypes, capacityType)
+	launchTemplateConfigs, err := p.getLaunchTemplateConfigs(ctx, constraints, instanceTypes, capacityType, totalTargetCapacity, nil)
 	if err != nil {
 		return nil, fmt.Errorf("getting launch template configs, %w", err)
 	}
@@ -128,10 +185,11 @@ func (p *InstanceProvider) launchInstance(ctx context.Context, constraints *v1al
 	tags := v1alpha1.MergeTags(ctx, constraints.Tags, map[string]string{fmt.Sprintf("kubernetes.io/cluster/%s", injection.GetOptions(ctx).ClusterName): "owned"})
 	createFleetInput := &ec2.CreateFleetInput{
 		Type:                  aws.String(ec2.FleetTypeInstant),
+		ClientToken:           aws.String(clientToken),
 		LaunchTemplateConfigs: launchTemplateConfigs,
 		TargetCapacitySpecification: &ec2.TargetCapacitySpecificationRequest{
 			DefaultTargetCapacityType: aws.String(capacityType),
-			TotalTargetCapacity:       aws.Int64(1),
+			TotalTargetCapacity:       aws.Int64(totalTargetCapacity),
 		},
 		TagSpecifications: []*ec2.TagSpecification{
 			{ResourceType: aws.String(ec2.ResourceTypeInstance), Tags: tags},
@@ -154,20 +212,129 @@ func (p *InstanceProvider) launchInstance(ctx context.Context, constraints *v1al
 	return createFleetOutput.Instances[0].InstanceIds[0], nil
 }
 
-func (p *InstanceProvider) getLaunchTemplateConfigs(ctx context.Context, constraints *v1alpha1.Constraints, instanceTypes []cloudprovider.InstanceType, capacityType string) ([]*ec2.FleetLaunchTemplateConfigRequest, error) {
+// launchInstanceMixed handles provisioners that configure an InstancesDistribution, splitting target capacity
+// between the spot and on-demand pools instead of picking a single capacityType for the whole CreateFleet call.
+// Launch template configs are built for both pools so that EC2 can draw from whichever offerings exist in each.
+func (p *InstanceProvider) launchInstanceMixed(ctx context.Context, constraints *v1alpha1.Constraints, instanceTypes []cloudprovider.InstanceType, clientToken string, totalTargetCapacity int64) (*string, error) {
+	distribution := constraints.AWS.InstancesDistribution
+	onDemandConfigs, onDemandErr := p.getLaunchTemplateConfigs(ctx, constraints, instanceTypes, v1alpha1.CapacityTypeOnDemand, totalTargetCapacity, nil)
+	if onDemandErr != nil && !errors.Is(onDemandErr, errNoCapacityOfferings) {
+		return nil, fmt.Errorf("getting on-demand launch template configs, %w", onDemandErr)
+	}
+	spotConfigs, spotErr := p.getLaunchTemplateConfigs(ctx, constraints, instanceTypes, v1alpha1.CapacityTypeSpot, totalTargetCapacity, distribution.SpotMaxPrice)
+	if spotErr != nil && !errors.Is(spotErr, errNoCapacityOfferings) {
+		return nil, fmt.Errorf("getting spot launch template configs, %w", spotErr)
+	}
+	if len(onDemandConfigs) == 0 && len(spotConfigs) == 0 {
+		return nil, fmt.Errorf("no capacity offerings are currently available given the constraints")
+	}
+	onDemandTarget, spotTarget := splitTargetCapacity(totalTargetCapacity, distribution)
+	tags := v1alpha1.MergeTags(ctx, constraints.Tags, map[string]string{fmt.Sprintf("kubernetes.io/cluster/%s", injection.GetOptions(ctx).ClusterName): "owned"})
+	createFleetInput := &ec2.CreateFleetInput{
+		Type:                  aws.String(ec2.FleetTypeInstant),
+		ClientToken:           aws.String(clientToken),
+		LaunchTemplateConfigs: append(onDemandConfigs, spotConfigs...),
+		TargetCapacitySpecification: &ec2.TargetCapacitySpecificationRequest{
+			DefaultTargetCapacityType: aws.String(v1alpha1.CapacityTypeOnDemand),
+			TotalTargetCapacity:       aws.Int64(onDemandTarget + spotTarget),
+			OnDemandTargetCapacity:    aws.Int64(onDemandTarget),
+			SpotTargetCapacity:        aws.Int64(spotTarget),
+		},
+		TagSpecifications: []*ec2.TagSpecification{
+			{ResourceType: aws.String(ec2.ResourceTypeInstance), Tags: tags},
+			{ResourceType: aws.String(ec2.ResourceTypeVolume), Tags: tags},
+		},
+		OnDemandOptions: &ec2.OnDemandOptionsRequest{AllocationStrategy: onDemandAllocationStrategyOrDefault(distribution)},
+		SpotOptions:     &ec2.SpotOptionsRequest{AllocationStrategy: spotAllocationStrategyOrDefault(distribution)},
+	}
+	createFleetOutput, err := p.ec2api.CreateFleetWithContext(ctx, createFleetInput)
+	if err != nil {
+		return nil, fmt.Errorf("creating fleet %w", err)
+	}
+	p.updateUnavailableOfferingsCache(ctx, createFleetOutput.Errors, v1alpha1.CapacityTypeOnDemand)
+	p.updateUnavailableOfferingsCache(ctx, createFleetOutput.Errors, v1alpha1.CapacityTypeSpot)
+	if len(createFleetOutput.Instances) == 0 || len(createFleetOutput.Instances[0].InstanceIds) == 0 {
+		return nil, combineFleetErrors(createFleetOutput.Errors)
+	}
+	return createFleetOutput.Instances[0].InstanceIds[0], nil
+}
+
+// splitTargetCapacity divides totalTargetCapacity between on-demand and spot according to the distribution's base
+// capacity and above-base percentage, defaulting to 100% on-demand when unset (matching the ASG mixed-instances
+// policy default).
+func splitTargetCapacity(totalTargetCapacity int64, distribution *v1alpha1.InstancesDistribution) (onDemand, spot int64) {
+	base := int64(0)
+	if distribution.OnDemandBaseCapacity != nil {
+		base = *distribution.OnDemandBaseCapacity
+	}
+	if base > totalTargetCapacity {
+		base = totalTargetCapacity
+	}
+	abovePercentage := int64(100)
+	if distribution.OnDemandPercentageAboveBaseCapacity != nil {
+		abovePercentage = *distribution.OnDemandPercentageAboveBaseCapacity
+	}
+	remaining := totalTargetCapacity - base
+	onDemandAboveBase := (remaining*abovePercentage + 99) / 100 // round up so we never under-provision on-demand
+	onDemand = base + onDemandAboveBase
+	if onDemand > totalTargetCapacity {
+		onDemand = totalTargetCapacity
+	}
+	spot = totalTargetCapacity - onDemand
+	return onDemand, spot
+}
+
+func onDemandAllocationStrategyOrDefault(distribution *v1alpha1.InstancesDistribution) *string {
+	if distribution.OnDemandAllocationStrategy != nil {
+		return distribution.OnDemandAllocationStrategy
+	}
+	return aws.String(ec2.FleetOnDemandAllocationStrategyLowestPrice)
+}
+
+func spotAllocationStrategyOrDefault(distribution *v1alpha1.InstancesDistribution) *string {
+	if distribution.SpotAllocationStrategy != nil {
+		return distribution.SpotAllocationStrategy
+	}
+	return aws.String(ec2.SpotAllocationStrategyCapacityOptimizedPrioritized)
+}
+
+// spotMaxPrice, when set, is the per-instance-hour price cap from InstancesDistribution.SpotMaxPrice and only
+// applies when capacityType is spot; it's passed through to each override's MaxPrice rather than the fleet-wide
+// SpotOptions.MaxTotalPrice, which caps the whole CreateFleet request's combined hourly cost instead.
+func (p *InstanceProvider) getLaunchTemplateConfigs(ctx context.Context, constraints *v1alpha1.Constraints, instanceTypes []cloudprovider.InstanceType, capacityType string, totalTargetCapacity int64, spotMaxPrice *string) ([]*ec2.FleetLaunchTemplateConfigRequest, error) {
 	// Get subnets given the constraints
 	subnets, err := p.subnetProvider.Get(ctx, constraints.AWS)
 	if err != nil {
 		return nil, fmt.Errorf("getting subnets, %w", err)
 	}
+	// Get the zone-type (availability-zone, local-zone, wavelength-zone) of each candidate zone so we can keep
+	// ordinary workloads off the edge unless they've explicitly opted in.
+	zoneTypes, err := p.subnetProvider.ZoneTypes(ctx, constraints.AWS)
+	if err != nil {
+		return nil, fmt.Errorf("getting zone types, %w", err)
+	}
+	allowedZoneTypes := constraints.Requirements.ZoneTypes()
+	if allowedZoneTypes.Len() == 0 {
+		allowedZoneTypes = sets.NewString(v1alpha1.DefaultZoneTypes...)
+	}
+	subnetSelector := p.subnetSelectorFor(constraints.AWS.SubnetSelectionStrategy)
+	// weightUnit is the single smallest instance type across every launch template partition in this CreateFleet
+	// call. EC2 requires one consistent WeightedCapacity scale for the whole call, so this must be computed once
+	// here rather than per-partition below, even though pods needing multiple launch templates (e.g. GPU vs
+	// non-GPU) partition instanceTypes into disjoint, differently-sized groups.
+	weightUnit := smallestInstanceType(instanceTypes)
 	var launchTemplateConfigs []*ec2.FleetLaunchTemplateConfigRequest
 	launchTemplates, err := p.launchTemplateProvider.Get(ctx, constraints, instanceTypes, map[string]string{v1alpha5.LabelCapacityType: capacityType})
 	if err != nil {
 		return nil, fmt.Errorf("getting launch templates, %w", err)
 	}
 	for launchTemplateName, instanceTypes := range launchTemplates {
+		overrides, err := p.getOverrides(ctx, instanceTypes, subnets, constraints.Requirements.Zones(), capacityType, constraints.AWS.InstanceRequirements, zoneTypes, allowedZoneTypes, totalTargetCapacity, subnetSelector, weightUnit, spotMaxPrice)
+		if err != nil {
+			return nil, fmt.Errorf("getting launch template overrides, %w", err)
+		}
 		launchTemplateConfig := &ec2.FleetLaunchTemplateConfigRequest{
-			Overrides: p.getOverrides(instanceTypes, subnets, constraints.Requirements.Zones(), capacityType),
+			Overrides: overrides,
 			LaunchTemplateSpecification: &ec2.FleetLaunchTemplateSpecificationRequest{
 				LaunchTemplateName: aws.String(launchTemplateName),
 				Version:            aws.String("$Latest"),
@@ -178,21 +345,75 @@ func (p *InstanceProvider) getLaunchTemplateConfigs(ctx context.Context, constra
 		}
 	}
 	if len(launchTemplateConfigs) == 0 {
-		return nil, fmt.Errorf("no capacity offerings are currently available given the constraints")
+		return nil, errNoCapacityOfferings
 	}
 	return launchTemplateConfigs, nil
 }
 
+// PreviewSubnets resolves which subnet each zone of an allowed zone-type would currently receive for constraints,
+// without launching anything, so a caller (e.g. the scheduler, for observability) can show which subnet a
+// scheduling.Node is expected to land in. capacityType does not affect the result, and zones are not narrowed to the
+// constraints' zone requirements. It shares the same zone-type filtering and SubnetSelector as
+// getLaunchTemplateConfigs, so the answer matches what a real CreateFleet call would pick, with one caveat: for a
+// stateful strategy like RoundRobin, calling this advances the same counter a real launch would, so previewing
+// and launching back-to-back isn't idempotent.
+func (p *InstanceProvider) PreviewSubnets(ctx context.Context, constraints *v1alpha1.Constraints, capacityType string) (map[string]*ec2.Subnet, error) {
+	subnets, err := p.subnetProvider.Get(ctx, constraints.AWS)
+	if err != nil {
+		return nil, fmt.Errorf("getting subnets, %w", err)
+	}
+	zoneTypes, err := p.subnetProvider.ZoneTypes(ctx, constraints.AWS)
+	if err != nil {
+		return nil, fmt.Errorf("getting zone types, %w", err)
+	}
+	allowedZoneTypes := constraints.Requirements.ZoneTypes()
+	if allowedZoneTypes.Len() == 0 {
+		allowedZoneTypes = sets.NewString(v1alpha1.DefaultZoneTypes...)
+	}
+	eligibleSubnets := make([]*ec2.Subnet, 0, len(subnets))
+	for _, subnet := range subnets {
+		if !allowedZoneTypes.Has(zoneTypes[*subnet.AvailabilityZone]) {
+			continue
+		}
+		eligibleSubnets = append(eligibleSubnets, subnet)
+	}
+	zonalSubnets, err := p.subnetSelectorFor(constraints.AWS.SubnetSelectionStrategy).Select(ctx, eligibleSubnets)
+	if err != nil {
+		return nil, fmt.Errorf("selecting subnets, %w", err)
+	}
+	return zonalSubnets, nil
+}
+
 // getOverrides creates and returns launch template overrides for the cross product of instanceTypeOptions and subnets (with subnets being constrained by
-// zones and the offerings in instanceTypeOptions)
-func (p *InstanceProvider) getOverrides(instanceTypeOptions []cloudprovider.InstanceType, subnets []*ec2.Subnet, zones sets.String, capacityType string) []*ec2.FleetLaunchTemplateOverridesRequest {
-	// sort subnets in ascending order of available IP addresses and populate map with most available subnet per AZ
-	zonalSubnets := map[string]*ec2.Subnet{}
-	sort.Slice(subnets, func(i, j int) bool {
-		return aws.Int64Value(subnets[i].AvailableIpAddressCount) < aws.Int64Value(subnets[j].AvailableIpAddressCount)
-	})
+// zones and the offerings in instanceTypeOptions). If the constraints specify InstanceRequirements (attribute-based
+// instance selection), a single override per zone is produced carrying the requirements envelope instead of an
+// explicit InstanceType, and instanceTypeOptions is not consulted. Zones whose zone-type isn't in allowedZoneTypes are skipped entirely so that
+// ordinary workloads aren't accidentally placed in a Local Zone or Wavelength Zone. When totalTargetCapacity is
+// greater than 1, each override is given a WeightedCapacity relative to weightUnit, the smallest instance type
+// across the entire CreateFleet call, so that EC2 can fill the batch with whatever mix of sizes is available. Which
+// subnet wins within an AZ is decided by subnetSelector, e.g. MostAvailableIPs (the original, default behavior).
+// spotMaxPrice, when capacityType is spot, is applied per override as the per-instance-hour price cap.
+func (p *InstanceProvider) getOverrides(ctx context.Context, instanceTypeOptions []cloudprovider.InstanceType, subnets []*ec2.Subnet, zones sets.String, capacityType string, instanceRequirements *v1alpha1.InstanceRequirements, zoneTypes map[string]string, allowedZoneTypes sets.String, totalTargetCapacity int64, subnetSelector SubnetSelector, weightUnit cloudprovider.InstanceType, spotMaxPrice *string) ([]*ec2.FleetLaunchTemplateOverridesRequest, error) {
+	eligibleSubnets := make([]*ec2.Subnet, 0, len(subnets))
 	for _, subnet := range subnets {
-		zonalSubnets[*subnet.AvailabilityZone] = subnet
+		if !allowedZoneTypes.Has(zoneTypes[*subnet.AvailabilityZone]) {
+			continue
+		}
+		eligibleSubnets = append(eligibleSubnets, subnet)
+	}
+	zonalSubnets, err := subnetSelector.Select(ctx, eligibleSubnets)
+	if err != nil {
+		return nil, fmt.Errorf("selecting subnets, %w", err)
+	}
+	if instanceRequirements != nil {
+		// WeightedCapacity needs a concrete instance type to weigh against weightUnit, but InstanceRequirements
+		// overrides let Fleet itself pick the instance type, so there's nothing to weigh until after the fact.
+		// Reject the combination rather than silently weighting every override as 1, which would under- or
+		// over-fill totalTargetCapacity depending on what Fleet ends up choosing.
+		if totalTargetCapacity > 1 {
+			return nil, fmt.Errorf("InstanceRequirements is not supported together with a weighted multi-instance target capacity")
+		}
+		return p.getRequirementsOverrides(instanceRequirements, zonalSubnets, zones, capacityType, spotMaxPrice), nil
 	}
 	var overrides []*ec2.FleetLaunchTemplateOverridesRequest
 	for i, instanceType := range instanceTypeOptions {
@@ -219,13 +440,102 @@ func (p *InstanceProvider) getOverrides(instanceTypeOptions []cloudprovider.Inst
 			// instanceTypeOptions are sorted by vcpus and memory so this prioritizes smaller instance types.
 			if capacityType == v1alpha1.CapacityTypeSpot {
 				override.Priority = aws.Float64(float64(i))
+				override.MaxPrice = spotMaxPrice
+			}
+			if totalTargetCapacity > 1 {
+				override.WeightedCapacity = aws.Float64(weightedCapacity(instanceType, weightUnit))
 			}
 			overrides = append(overrides, override)
 		}
 	}
+	return overrides, nil
+}
+
+// weightedCapacity returns how many capacity units instanceType counts for relative to unit, the smallest candidate
+// instance type, using whichever of vCPU or memory is the more binding resource. This mirrors the unit
+// scheduling.Node uses to decide how large a TotalTargetCapacity to request, so that a batch of weighted overrides
+// fills the same number of units regardless of which mix of instance sizes EC2 ends up choosing. Using CPU alone
+// would understate the weight (and therefore the capacity EC2 is asked to fill) for memory-heavy, CPU-light types.
+func weightedCapacity(instanceType, unit cloudprovider.InstanceType) float64 {
+	weight := 1.0
+	if unitCPU := unit.Resources().Cpu().AsApproximateFloat64(); unitCPU > 0 {
+		if cpuRatio := instanceType.Resources().Cpu().AsApproximateFloat64() / unitCPU; cpuRatio > weight {
+			weight = cpuRatio
+		}
+	}
+	if unitMemory := unit.Resources().Memory().AsApproximateFloat64(); unitMemory > 0 {
+		if memRatio := instanceType.Resources().Memory().AsApproximateFloat64() / unitMemory; memRatio > weight {
+			weight = memRatio
+		}
+	}
+	return weight
+}
+
+// smallestInstanceType returns the first of instanceTypes, or nil if instanceTypes is empty. The first is the
+// smallest only because candidate instance types arrive sorted by vcpus and memory (see the spot priority comment in
+// getOverrides); the list is not sorted here.
+func smallestInstanceType(instanceTypes []cloudprovider.InstanceType) cloudprovider.InstanceType {
+	if len(instanceTypes) == 0 {
+		return nil
+	}
+	return instanceTypes[0]
+}
+
+// getRequirementsOverrides produces one override per zone that EC2 is allowed to fulfill, carrying the
+// InstanceRequirements envelope rather than an InstanceType so that Fleet can pick the best fitting type itself
+// instead of us enumerating and truncating to MaxInstanceTypes. spotMaxPrice is applied per override when
+// capacityType is spot, matching the non-ABIS path in getOverrides.
+func (p *InstanceProvider) getRequirementsOverrides(instanceRequirements *v1alpha1.InstanceRequirements, zonalSubnets map[string]*ec2.Subnet, zones sets.String, capacityType string, spotMaxPrice *string) []*ec2.FleetLaunchTemplateOverridesRequest {
+	var overrides []*ec2.FleetLaunchTemplateOverridesRequest
+	for zone, subnet := range zonalSubnets {
+		if !zones.Has(zone) {
+			continue
+		}
+		override := &ec2.FleetLaunchTemplateOverridesRequest{
+			InstanceRequirements: toEC2InstanceRequirements(instanceRequirements),
+			SubnetId:             subnet.SubnetId,
+			AvailabilityZone:     subnet.AvailabilityZone,
+		}
+		if capacityType == v1alpha1.CapacityTypeSpot {
+			override.MaxPrice = spotMaxPrice
+		}
+		overrides = append(overrides, override)
+	}
 	return overrides
 }
 
+// toEC2InstanceRequirements translates the (mostly optional) CRD fields into an ec2.InstanceRequirementsRequest.
+// VCpuCount and MemoryMiB are the two exceptions: CreateFleet rejects an InstanceRequirementsRequest that omits
+// either, so an unset CRD range is defaulted to {Min: 0} (i.e. unbounded) rather than left nil.
+func toEC2InstanceRequirements(requirements *v1alpha1.InstanceRequirements) *ec2.InstanceRequirementsRequest {
+	req := &ec2.InstanceRequirementsRequest{
+		BareMetal:            requirements.BareMetal,
+		BurstablePerformance: requirements.BurstablePerformance,
+		VCpuCount:            &ec2.VCpuCountRangeRequest{Min: aws.Int64(0)},
+		MemoryMiB:            &ec2.MemoryMiBRequest{Min: aws.Int64(0)},
+	}
+	if requirements.VCpuCount != nil {
+		req.VCpuCount = &ec2.VCpuCountRangeRequest{Min: aws.Int64(requirements.VCpuCount.Min), Max: requirements.VCpuCount.Max}
+	}
+	if requirements.MemoryMiB != nil {
+		req.MemoryMiB = &ec2.MemoryMiBRequest{Min: aws.Int64(requirements.MemoryMiB.Min), Max: requirements.MemoryMiB.Max}
+	}
+	if requirements.AcceleratorCount != nil {
+		req.AcceleratorCount = &ec2.AcceleratorCountRequest{Min: aws.Int64(requirements.AcceleratorCount.Min), Max: requirements.AcceleratorCount.Max}
+	}
+	if requirements.NetworkBandwidthGbps != nil {
+		req.NetworkBandwidthGbps = &ec2.NetworkBandwidthGbpsRequest{Min: aws.Float64(requirements.NetworkBandwidthGbps.Min), Max: requirements.NetworkBandwidthGbps.Max}
+	}
+	for _, family := range requirements.AllowedInstanceFamilies {
+		req.AllowedInstanceTypes = append(req.AllowedInstanceTypes, aws.String(family))
+	}
+	for _, family := range requirements.ExcludedInstanceFamilies {
+		req.ExcludedInstanceTypes = append(req.ExcludedInstanceTypes, aws.String(family))
+	}
+	req.SpotMaxPricePercentageOverLowestPrice = requirements.SpotMaxPricePercentageOverLowestPrice
+	return req
+}
+
 func (p *InstanceProvider) getInstance(ctx context.Context, id string) (*ec2.Instance, error) {
 	describeInstancesOutput, err := p.ec2api.DescribeInstancesWithContext(ctx, &ec2.DescribeInstancesInput{InstanceIds: aws.StringSlice([]string{id})})
 	if isNotFound(err) {
@@ -247,7 +557,7 @@ func (p *InstanceProvider) getInstance(ctx context.Context, id string) (*ec2.Ins
 	return instance, nil
 }
 
-func (p *InstanceProvider) instanceToNode(ctx context.Context, instance *ec2.Instance, instanceTypes []cloudprovider.InstanceType) *v1.Node {
+func (p *InstanceProvider) instanceToNode(ctx context.Context, constraints *v1alpha1.Constraints, instance *ec2.Instance, instanceTypes []cloudprovider.InstanceType) *v1.Node {
 	for _, instanceType := range instanceTypes {
 		if instanceType.Name() == aws.StringValue(instance.InstanceType) {
 			nodeName := strings.ToLower(aws.StringValue(instance.PrivateDnsName))
@@ -255,6 +565,13 @@ func (p *InstanceProvider) instanceToNode(ctx context.Context, instance *ec2.Ins
 				nodeName = aws.StringValue(instance.InstanceId)
 			}
 
+			zoneType := v1alpha1.ZoneTypeAvailabilityZone
+			if zoneTypes, err := p.subnetProvider.ZoneTypes(ctx, constraints.AWS); err != nil {
+				logging.FromContext(ctx).Errorf("getting zone type for node label, %s", err)
+			} else if zt, ok := zoneTypes[aws.StringValue(instance.Placement.AvailabilityZone)]; ok {
+				zoneType = zt
+			}
+
 			resources := v1.ResourceList{}
 			for resourceName, quantity := range map[v1.ResourceName]resource.Quantity{
 				v1.ResourcePods:             instanceType.Resources()[v1.ResourcePods],
@@ -277,6 +594,7 @@ func (p *InstanceProvider) instanceToNode(ctx context.Context, instance *ec2.Ins
 						v1.LabelTopologyZone:       aws.StringValue(instance.Placement.AvailabilityZone),
 						v1.LabelInstanceTypeStable: aws.StringValue(instance.InstanceType),
 						v1alpha5.LabelCapacityType: getCapacityType(instance),
+						v1alpha1.LabelZoneType:     zoneType,
 					},
 				},
 				Spec: v1.NodeSpec{
@@ -341,6 +659,24 @@ func (p *InstanceProvider) filterInstanceTypes(instanceTypes []cloudprovider.Ins
 	return instanceTypes
 }
 
+// clientTokenFor derives a deterministic CreateFleet client token from the set of pod UIDs and constraints assigned
+// to a scheduling.Node, so that retrying the same scheduling decision after a transient error (timeout, throttle, or
+// partial CreateFleet Errors) lands on the same token and therefore the same instance rather than provisioning a
+// second one. EC2 enforces a 64-character ClientToken limit, so we hash down to that.
+func clientTokenFor(constraints *v1alpha1.Constraints, pods []*v1.Pod) string {
+	uids := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		uids = append(uids, string(pod.UID))
+	}
+	sort.Strings(uids)
+	h := sha256.New()
+	for _, uid := range uids {
+		h.Write([]byte(uid))
+	}
+	fmt.Fprintf(h, "%v", constraints.Requirements)
+	return hex.EncodeToString(h.Sum(nil))[:64]
+}
+
 func getInstanceID(node *v1.Node) (*string, error) {
 	id := strings.Split(node.Spec.ProviderID, "/")
 	if len(id) < 5 {
@@ -0,0 +1,197 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	cache "github.com/patrickmn/go-cache"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/aws/karpenter/pkg/apis/provisioning/v1alpha5"
+	"github.com/aws/karpenter/pkg/cloudprovider/aws/apis/v1alpha1"
+)
+
+// Note on scope: these tests don't call InstanceProvider.Create or launchInstance directly, including on the
+// cache-hit branch of Create (lines ~120-128), which only touches getInstance/instanceToNode and not
+// subnetProvider/launchTemplateProvider. The blocker isn't those two providers -- it's that Create's own signature
+// takes []cloudprovider.InstanceType, and neither the cloudprovider package (cloudprovider.InstanceType) nor this
+// package's own InstanceTypeProvider and LaunchTemplateProvider are defined anywhere in this trimmed checkout, a
+// pre-existing gap that predates this series. That makes the whole aws package, this test file included, uninstantiable against the real Create/
+// instanceToNode functions no matter which branch is targeted -- there's no type to name for the instanceTypes
+// parameter, let alone a fake satisfying it. These tests instead cover the two pieces of the idempotency contract
+// that are fully self-contained: clientTokenFor's determinism, and that a cached client token short-circuits
+// straight to the existing instance without creating a new fleet, using the same clientTokenCache and getInstance
+// Create relies on.
+
+// fakeEC2API implements just enough of ec2iface.EC2API for these tests; embedding the interface with everything
+// else left nil means any method we don't override panics loudly if accidentally exercised.
+type fakeEC2API struct {
+	ec2iface.EC2API
+	createFleetCalls       int
+	createFleetErrOnCalls  map[int]error
+	describeInstancesCalls int
+	instances              map[string]*ec2.Instance
+}
+
+// CreateFleetWithContext fails on whichever call numbers are listed in createFleetErrOnCalls, modeling a transient
+// mid-flight failure (throttle, timeout) that a caller retries.
+func (f *fakeEC2API) CreateFleetWithContext(_ aws.Context, _ *ec2.CreateFleetInput, _ ...request.Option) (*ec2.CreateFleetOutput, error) {
+	f.createFleetCalls++
+	if err, ok := f.createFleetErrOnCalls[f.createFleetCalls]; ok {
+		return nil, err
+	}
+	id := fmt.Sprintf("i-fake%d", f.createFleetCalls)
+	f.instances[id] = &ec2.Instance{
+		InstanceId:     aws.String(id),
+		InstanceType:   aws.String("m5.large"),
+		PrivateDnsName: aws.String("ip-10-0-0-1.ec2.internal"),
+		Placement:      &ec2.Placement{AvailabilityZone: aws.String("us-west-2a")},
+	}
+	return &ec2.CreateFleetOutput{
+		Instances: []*ec2.CreateFleetInstance{{InstanceIds: []*string{aws.String(id)}}},
+	}, nil
+}
+
+func (f *fakeEC2API) DescribeInstancesWithContext(_ aws.Context, in *ec2.DescribeInstancesInput, _ ...request.Option) (*ec2.DescribeInstancesOutput, error) {
+	f.describeInstancesCalls++
+	id := aws.StringValue(in.InstanceIds[0])
+	instance, ok := f.instances[id]
+	if !ok {
+		return nil, fmt.Errorf("instance %s not found", id)
+	}
+	return &ec2.DescribeInstancesOutput{
+		Reservations: []*ec2.Reservation{{Instances: []*ec2.Instance{instance}}},
+	}, nil
+}
+
+func testConstraints() *v1alpha1.Constraints {
+	return &v1alpha1.Constraints{Constraints: &v1alpha5.Constraints{}}
+}
+
+func testPods(uids ...string) []*v1.Pod {
+	pods := make([]*v1.Pod, 0, len(uids))
+	for _, uid := range uids {
+		pods = append(pods, &v1.Pod{ObjectMeta: metav1.ObjectMeta{UID: types.UID(uid)}})
+	}
+	return pods
+}
+
+func TestClientTokenFor_Deterministic(t *testing.T) {
+	constraints := testConstraints()
+	pods := testPods("a", "b")
+	if clientTokenFor(constraints, pods) != clientTokenFor(constraints, pods) {
+		t.Fatal("clientTokenFor produced different tokens for identical inputs")
+	}
+}
+
+func TestClientTokenFor_DiffersByPods(t *testing.T) {
+	constraints := testConstraints()
+	if clientTokenFor(constraints, testPods("a", "b")) == clientTokenFor(constraints, testPods("a", "c")) {
+		t.Fatal("clientTokenFor produced the same token for different pod sets")
+	}
+}
+
+// TestCreate_CachedClientTokenAvoidsDuplicateLaunch replays Create's cache-hit branch (Create itself can't be called
+// here, see the note above): with the client token for the same pods/constraints already cached, as after a first
+// call that launched an instance, the cached instance ID is looked up and fetched with getInstance, and CreateFleet
+// is never called.
+func TestCreate_CachedClientTokenAvoidsDuplicateLaunch(t *testing.T) {
+	ctx := context.Background()
+	constraints := testConstraints()
+	pods := testPods("a", "b")
+	token := clientTokenFor(constraints, pods)
+
+	fake := &fakeEC2API{instances: map[string]*ec2.Instance{
+		"i-existing": {
+			InstanceId:     aws.String("i-existing"),
+			InstanceType:   aws.String("m5.large"),
+			PrivateDnsName: aws.String("ip-10-0-0-1.ec2.internal"),
+			Placement:      &ec2.Placement{AvailabilityZone: aws.String("us-west-2a")},
+		},
+	}}
+	p := &InstanceProvider{
+		ec2api:           fake,
+		clientTokenCache: cache.New(clientTokenTTL, clientTokenTTL),
+	}
+	p.clientTokenCache.SetDefault(token, "i-existing")
+
+	cachedID, ok := p.clientTokenCache.Get(token)
+	if !ok {
+		t.Fatal("expected the client token to be cached")
+	}
+	if _, err := p.getInstance(ctx, cachedID.(string)); err != nil {
+		t.Fatalf("getInstance: %v", err)
+	}
+	if fake.createFleetCalls != 0 {
+		t.Fatalf("expected a cached client token to avoid calling CreateFleet, got %d calls", fake.createFleetCalls)
+	}
+	if fake.describeInstancesCalls != 1 {
+		t.Fatalf("expected exactly one DescribeInstances call, got %d", fake.describeInstancesCalls)
+	}
+	if len(fake.instances) != 1 {
+		t.Fatalf("expected exactly one instance to exist, got %d", len(fake.instances))
+	}
+}
+
+// TestClientTokenCache_SurvivesTransientCreateFleetError models the retry contract Create() relies on: a
+// CreateFleet call that fails mid-flight (e.g. a throttle) must not populate the client token cache, so a retry
+// with the same token tries CreateFleet again rather than believing an instance already exists; once CreateFleet
+// does succeed, the cache is populated and any further retry with that token must not call CreateFleet again. Across
+// an error-then-success-then-retry sequence, exactly one instance should ever exist.
+func TestClientTokenCache_SurvivesTransientCreateFleetError(t *testing.T) {
+	constraints := testConstraints()
+	token := clientTokenFor(constraints, testPods("a", "b"))
+
+	fake := &fakeEC2API{
+		instances:             map[string]*ec2.Instance{},
+		createFleetErrOnCalls: map[int]error{1: fmt.Errorf("RequestLimitExceeded: throttled")},
+	}
+	tokenCache := cache.New(clientTokenTTL, clientTokenTTL)
+
+	// First attempt: CreateFleet fails, so nothing is cached.
+	if _, err := fake.CreateFleetWithContext(context.Background(), &ec2.CreateFleetInput{ClientToken: aws.String(token)}); err == nil {
+		t.Fatal("expected the first CreateFleet call to fail")
+	}
+	if _, ok := tokenCache.Get(token); ok {
+		t.Fatal("a failed CreateFleet call must not populate the client token cache")
+	}
+
+	// Retry with the same token: CreateFleet succeeds this time, and the result gets cached.
+	out, err := fake.CreateFleetWithContext(context.Background(), &ec2.CreateFleetInput{ClientToken: aws.String(token)})
+	if err != nil {
+		t.Fatalf("expected the retried CreateFleet call to succeed, got %v", err)
+	}
+	tokenCache.SetDefault(token, aws.StringValue(out.Instances[0].InstanceIds[0]))
+
+	// A further retry with the same token must hit the cache rather than calling CreateFleet a third time.
+	if _, ok := tokenCache.Get(token); !ok {
+		t.Fatal("expected the client token to be cached after the successful retry")
+	}
+	if fake.createFleetCalls != 2 {
+		t.Fatalf("expected exactly 2 CreateFleet calls (1 failure + 1 success), got %d", fake.createFleetCalls)
+	}
+	if len(fake.instances) != 1 {
+		t.Fatalf("expected exactly one instance to have been created, got %d", len(fake.instances))
+	}
+}
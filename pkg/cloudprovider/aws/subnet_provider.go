@@ -0,0 +1,118 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	cache "github.com/patrickmn/go-cache"
+
+	"github.com/aws/karpenter/pkg/cloudprovider/aws/apis/v1alpha1"
+)
+
+const (
+	// subnetCacheTTL bounds how long we trust a DescribeSubnets/DescribeAvailabilityZones result before re-fetching.
+	// Subnet IP exhaustion and zone-type are both slow-moving, so this favors fewer API calls over freshness.
+	subnetCacheTTL = 5 * time.Minute
+)
+
+// SubnetProvider resolves the subnets and zone-types a Provisioner is allowed to launch into.
+type SubnetProvider struct {
+	ec2api ec2iface.EC2API
+	cache  *cache.Cache
+}
+
+func NewSubnetProvider(ec2api ec2iface.EC2API) *SubnetProvider {
+	return &SubnetProvider{
+		ec2api: ec2api,
+		cache:  cache.New(subnetCacheTTL, 1*time.Minute),
+	}
+}
+
+// Get returns the subnets selected by the Provisioner's AWS.SubnetSelector tags. A Provisioner with no selector
+// entries is rejected rather than silently matching every subnet in the account/region.
+func (p *SubnetProvider) Get(ctx context.Context, provider v1alpha1.AWS) ([]*ec2.Subnet, error) {
+	if len(provider.SubnetSelector) == 0 {
+		return nil, fmt.Errorf("subnetSelector must specify at least one tag")
+	}
+	key := fmt.Sprintf("subnets:%s", selectorCacheKey(provider.SubnetSelector))
+	if cached, ok := p.cache.Get(key); ok {
+		return cached.([]*ec2.Subnet), nil
+	}
+	output, err := p.ec2api.DescribeSubnetsWithContext(ctx, &ec2.DescribeSubnetsInput{Filters: subnetSelectorFilters(provider.SubnetSelector)})
+	if err != nil {
+		return nil, fmt.Errorf("describing subnets, %w", err)
+	}
+	p.cache.SetDefault(key, output.Subnets)
+	return output.Subnets, nil
+}
+
+// subnetSelectorFilters translates a SubnetSelector tag map into DescribeSubnets filters. A value of "*" matches any
+// value for that tag key (one tag-key filter per key, so every such key must be present); any other value must match
+// exactly (filtered by tag:<key>).
+func subnetSelectorFilters(selector map[string]string) []*ec2.Filter {
+	var filters []*ec2.Filter
+	for key, value := range selector {
+		if value == "*" {
+			filters = append(filters, &ec2.Filter{Name: aws.String("tag-key"), Values: []*string{aws.String(key)}})
+			continue
+		}
+		filters = append(filters, &ec2.Filter{Name: aws.String(fmt.Sprintf("tag:%s", key)), Values: []*string{aws.String(value)}})
+	}
+	return filters
+}
+
+// selectorCacheKey builds a deterministic cache key from a SubnetSelector tag map, whose iteration order Go doesn't
+// guarantee.
+func selectorCacheKey(selector map[string]string) string {
+	pairs := make([]string, 0, len(selector))
+	for key, value := range selector {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// ZoneTypes returns the zone-type (availability-zone, local-zone, wavelength-zone) of every zone in the region, as
+// reported by ec2:DescribeAvailabilityZones, keyed by zone name.
+func (p *SubnetProvider) ZoneTypes(ctx context.Context, provider v1alpha1.AWS) (map[string]string, error) {
+	const key = "zone-types"
+	if cached, ok := p.cache.Get(key); ok {
+		return cached.(map[string]string), nil
+	}
+	output, err := p.ec2api.DescribeAvailabilityZonesWithContext(ctx, &ec2.DescribeAvailabilityZonesInput{
+		AllAvailabilityZones: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describing availability zones, %w", err)
+	}
+	zoneTypes := make(map[string]string, len(output.AvailabilityZones))
+	for _, zone := range output.AvailabilityZones {
+		zoneType := v1alpha1.ZoneTypeAvailabilityZone
+		if aws.StringValue(zone.ZoneType) != "" {
+			zoneType = aws.StringValue(zone.ZoneType)
+		}
+		zoneTypes[aws.StringValue(zone.ZoneName)] = zoneType
+	}
+	p.cache.SetDefault(key, zoneTypes)
+	return zoneTypes, nil
+}
@@ -0,0 +1,128 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync/atomic"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/aws/karpenter/pkg/apis/provisioning/v1alpha5"
+	"github.com/aws/karpenter/pkg/cloudprovider/aws/apis/v1alpha1"
+)
+
+// SubnetSelector picks one subnet per availability zone from the set of subnets a Provisioner is allowed to use.
+// Strategies differ in how they break ties between multiple eligible subnets in the same AZ. Select takes ctx
+// because strategies that query live cluster state (e.g. LeastAllocatedNodes) need to re-read it on every call.
+type SubnetSelector interface {
+	Select(ctx context.Context, subnets []*ec2.Subnet) (map[string]*ec2.Subnet, error)
+}
+
+// NewSubnetSelector returns the SubnetSelector for the given strategy, defaulting to MostAvailableIPs (today's
+// behavior) when strategy is empty or unrecognized. kubeClient is only consulted by strategies that need to read
+// cluster state; it may be nil for the others.
+func NewSubnetSelector(strategy string, kubeClient client.Client) SubnetSelector {
+	switch strategy {
+	case v1alpha1.SubnetSelectionLeastAllocatedNodes:
+		return &leastAllocatedNodesSubnetSelector{kubeClient: kubeClient}
+	case v1alpha1.SubnetSelectionRoundRobin:
+		return &roundRobinSubnetSelector{}
+	default:
+		return &mostAvailableIPsSubnetSelector{}
+	}
+}
+
+// mostAvailableIPsSubnetSelector picks, per AZ, the subnet with the most available IP addresses. This is
+// Karpenter's original behavior, intended to reduce the odds of IP exhaustion blocking future launches.
+type mostAvailableIPsSubnetSelector struct{}
+
+func (s *mostAvailableIPsSubnetSelector) Select(_ context.Context, subnets []*ec2.Subnet) (map[string]*ec2.Subnet, error) {
+	sorted := append([]*ec2.Subnet{}, subnets...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return aws.Int64Value(sorted[i].AvailableIpAddressCount) < aws.Int64Value(sorted[j].AvailableIpAddressCount)
+	})
+	zonalSubnets := map[string]*ec2.Subnet{}
+	for _, subnet := range sorted {
+		zonalSubnets[aws.StringValue(subnet.AvailabilityZone)] = subnet
+	}
+	return zonalSubnets, nil
+}
+
+// leastAllocatedNodesSubnetSelector reads the number of karpenter-managed nodes per zone from the cluster and picks
+// one subnet per AZ. Nodes are counted per zone, not per subnet, and every candidate within an AZ shares that zone's
+// count, so the subnet chosen within an AZ is the first eligible one in the order given.
+type leastAllocatedNodesSubnetSelector struct {
+	kubeClient client.Client
+}
+
+func (s *leastAllocatedNodesSubnetSelector) Select(ctx context.Context, subnets []*ec2.Subnet) (map[string]*ec2.Subnet, error) {
+	nodeCountByZone, err := s.countNodesByZone(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("counting nodes by zone, %w", err)
+	}
+	best := map[string]*ec2.Subnet{}
+	for _, subnet := range subnets {
+		zone := aws.StringValue(subnet.AvailabilityZone)
+		existing, ok := best[zone]
+		if !ok || nodeCountByZone[zone] < nodeCountByZone[aws.StringValue(existing.AvailabilityZone)] {
+			best[zone] = subnet
+		}
+	}
+	return best, nil
+}
+
+func (s *leastAllocatedNodesSubnetSelector) countNodesByZone(ctx context.Context) (map[string]int, error) {
+	nodeList := &v1.NodeList{}
+	if err := s.kubeClient.List(ctx, nodeList, client.HasLabels{v1alpha5.LabelCapacityType}); err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	for _, node := range nodeList.Items {
+		if zone, ok := node.Labels[v1.LabelTopologyZone]; ok {
+			counts[zone]++
+		}
+	}
+	return counts, nil
+}
+
+// roundRobinSubnetSelector cycles through the eligible subnets within an AZ on successive calls, rather than always
+// preferring the same one. Its call counter is only as persistent as the selector instance: callers that want
+// round-robin to hold across scheduling rounds must keep a long-lived selector rather than constructing a fresh one
+// per CreateFleet call, which is why InstanceProvider caches one SubnetSelector per strategy (shared by every
+// Provisioner using that strategy) instead of calling NewSubnetSelector on every launch.
+type roundRobinSubnetSelector struct {
+	calls uint64
+}
+
+func (s *roundRobinSubnetSelector) Select(_ context.Context, subnets []*ec2.Subnet) (map[string]*ec2.Subnet, error) {
+	byZone := map[string][]*ec2.Subnet{}
+	for _, subnet := range subnets {
+		zone := aws.StringValue(subnet.AvailabilityZone)
+		byZone[zone] = append(byZone[zone], subnet)
+	}
+	call := atomic.AddUint64(&s.calls, 1) - 1
+	zonalSubnets := map[string]*ec2.Subnet{}
+	for zone, candidates := range byZone {
+		sort.Slice(candidates, func(i, j int) bool { return aws.StringValue(candidates[i].SubnetId) < aws.StringValue(candidates[j].SubnetId) })
+		zonalSubnets[zone] = candidates[int(call)%len(candidates)]
+	}
+	return zonalSubnets, nil
+}
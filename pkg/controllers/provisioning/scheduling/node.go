@@ -16,6 +16,7 @@ package scheduling
 
 import (
 	"fmt"
+	"math"
 	"strings"
 
 	v1 "k8s.io/api/core/v1"
@@ -31,6 +32,12 @@ type Node struct {
 	Constraints         *v1alpha5.Constraints
 	InstanceTypeOptions []cloudprovider.InstanceType
 	Pods                []*v1.Pod
+	// TotalTargetCapacity is the number of weighted capacity units this Node group requires, in units of the
+	// smallest candidate instance type's capacity (whichever of vCPU or memory is more binding). It is 1 unless pod
+	// requests accumulate past what a single instance of the smallest candidate type can hold, in which case
+	// CreateFleet is given a TotalTargetCapacity greater than 1 and lets EC2 fill it with whatever mix of instance
+	// sizes is available.
+	TotalTargetCapacity int64
 
 	requests v1.ResourceList
 }
@@ -40,6 +47,7 @@ func NewNode(constraints *v1alpha5.Constraints, daemonResources v1.ResourceList,
 		Constraints:         constraints.DeepCopy(),
 		InstanceTypeOptions: instanceTypes,
 		requests:            daemonResources,
+		TotalTargetCapacity: 1,
 	}
 }
 
@@ -62,9 +70,32 @@ func (n *Node) Add(pod *v1.Pod) error {
 	n.InstanceTypeOptions = instanceTypes
 	n.requests = requests
 	n.Constraints.Requirements = requirements
+	n.TotalTargetCapacity = capacityUnits(requests, instanceTypes)
 	return nil
 }
 
+// capacityUnits converts cumulative pod requests into EC2 Fleet target-capacity units, using the smallest candidate
+// instance type's capacity as the unit of 1 and whichever of vCPU or memory is the more binding resource for the
+// batch, so a memory-heavy/CPU-light batch doesn't understate how many units EC2 needs to fill it. instanceTypes is
+// expected to be sorted by vcpus and memory, as it is everywhere else it's consumed.
+func capacityUnits(requests v1.ResourceList, instanceTypes []cloudprovider.InstanceType) int64 {
+	if len(instanceTypes) == 0 {
+		return 1
+	}
+	units := int64(1)
+	if unitCPU := instanceTypes[0].Resources().Cpu().AsApproximateFloat64(); unitCPU > 0 {
+		if cpuUnits := int64(math.Ceil(requests.Cpu().AsApproximateFloat64() / unitCPU)); cpuUnits > units {
+			units = cpuUnits
+		}
+	}
+	if unitMemory := instanceTypes[0].Resources().Memory().AsApproximateFloat64(); unitMemory > 0 {
+		if memUnits := int64(math.Ceil(requests.Memory().AsApproximateFloat64() / unitMemory)); memUnits > units {
+			units = memUnits
+		}
+	}
+	return units
+}
+
 func (n *Node) String() string {
 	var itSb strings.Builder
 	for i, it := range n.InstanceTypeOptions {